@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// weakETag formats a task's version as a weak ETag, e.g. W/"3".
+func weakETag(version int64) string {
+	return fmt.Sprintf(`W/"%d"`, version)
+}
+
+// parseETagVersion extracts the version encoded in a single ETag value
+// produced by weakETag, tolerating the optional "W/" weak prefix.
+func parseETagVersion(etag string) (int64, bool) {
+	etag = strings.TrimSpace(etag)
+	etag = strings.TrimPrefix(etag, "W/")
+	etag = strings.Trim(etag, `"`)
+	version, err := strconv.ParseInt(etag, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return version, true
+}
+
+// setTaskHeaders writes the ETag and Last-Modified headers describing task.
+func setTaskHeaders(w http.ResponseWriter, task Task) {
+	w.Header().Set("ETag", weakETag(task.Version))
+	w.Header().Set("Last-Modified", task.UpdatedAt.UTC().Format(http.TimeFormat))
+}
+
+// matchesETagList reports whether any entry in a comma-separated If-Match /
+// If-None-Match header value matches version, honoring "*" as a wildcard
+// that matches any existing resource.
+func matchesETagList(header string, version int64) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if v, ok := parseETagVersion(candidate); ok && v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// checkWritePreconditions enforces If-Match and If-Unmodified-Since against
+// the task's current state. ok is false when the write must be rejected;
+// status then holds the HTTP status to return (412 or 428).
+func checkWritePreconditions(r *http.Request, current Task, requirePreconditions bool) (ok bool, status int) {
+	ifMatch := r.Header.Get("If-Match")
+	ifUnmodifiedSince := r.Header.Get("If-Unmodified-Since")
+
+	if ifMatch == "" && ifUnmodifiedSince == "" {
+		if requirePreconditions {
+			return false, http.StatusPreconditionRequired
+		}
+		return true, 0
+	}
+
+	if ifMatch != "" && !matchesETagList(ifMatch, current.Version) {
+		return false, http.StatusPreconditionFailed
+	}
+
+	if ifUnmodifiedSince != "" {
+		since, err := http.ParseTime(ifUnmodifiedSince)
+		if err != nil {
+			return false, http.StatusBadRequest
+		}
+		if current.UpdatedAt.Truncate(time.Second).After(since) {
+			return false, http.StatusPreconditionFailed
+		}
+	}
+
+	return true, 0
+}
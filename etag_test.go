@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetTaskHandler_ETagAndIfNoneMatch(t *testing.T) {
+	srv := newTestServer()
+	srv.store.Create(context.Background(), "tracked")
+
+	req, _ := newRequestWithChiContext("GET", "/tasks/1", nil, map[string]string{"taskID": "1"})
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(srv.getTaskHandler).ServeHTTP(rr, req)
+
+	etag := rr.Header().Get("ETag")
+	if etag == "" {
+		t.Fatalf("expected an ETag header on GET")
+	}
+	if rr.Header().Get("Last-Modified") == "" {
+		t.Errorf("expected a Last-Modified header on GET")
+	}
+
+	req, _ = newRequestWithChiContext("GET", "/tasks/1", nil, map[string]string{"taskID": "1"})
+	req.Header.Set("If-None-Match", etag)
+	rr = httptest.NewRecorder()
+	http.HandlerFunc(srv.getTaskHandler).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusNotModified {
+		t.Errorf("got status %d, want %d", rr.Code, http.StatusNotModified)
+	}
+}
+
+func TestUpdateTaskHandler_IfMatchConflict(t *testing.T) {
+	srv := newTestServer()
+	srv.store.Create(context.Background(), "original")
+
+	req, _ := newRequestWithChiContext("PUT", "/tasks/1", bytes.NewBufferString(`{"done": true}`), map[string]string{"taskID": "1"})
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", weakETag(999))
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(srv.updateTaskHandler).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusPreconditionFailed {
+		t.Errorf("got status %d, want %d", rr.Code, http.StatusPreconditionFailed)
+	}
+}
+
+func TestUpdateTaskHandler_RequirePreconditions(t *testing.T) {
+	srv := newTestServer()
+	srv.requirePreconditions = true
+	srv.store.Create(context.Background(), "original")
+
+	req, _ := newRequestWithChiContext("PUT", "/tasks/1", bytes.NewBufferString(`{"done": true}`), map[string]string{"taskID": "1"})
+	req.Header.Set("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(srv.updateTaskHandler).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusPreconditionRequired {
+		t.Errorf("got status %d, want %d", rr.Code, http.StatusPreconditionRequired)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestRunServer_DrainsInFlightRequestsAndRejectsNewOnes(t *testing.T) {
+	srv := newTestServer()
+
+	releaseSlowRequest := make(chan struct{})
+	r := chi.NewRouter()
+	r.Get("/healthz", srv.healthCheckHandler)
+	r.Get("/slow", func(w http.ResponseWriter, r *http.Request) {
+		<-releaseSlowRequest
+		w.WriteHeader(http.StatusOK)
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("could not listen: %v", err)
+	}
+	addr := ln.Addr().String()
+
+	httpServer := &http.Server{Handler: r}
+	sigCh := make(chan os.Signal, 1)
+
+	// The drain delay must comfortably outlast the /healthz probe below so
+	// the listener is still guaranteed open when it runs, proving the 503
+	// is observed before Shutdown closes the listener rather than by luck.
+	const drainDelay = 300 * time.Millisecond
+
+	runErr := make(chan error, 1)
+	go func() {
+		httpServer.Serve(ln)
+	}()
+	go func() {
+		runErr <- runServer(httpServer, srv, time.Second, drainDelay, sigCh)
+	}()
+
+	slowResp := make(chan *http.Response, 1)
+	go func() {
+		resp, err := http.Get("http://" + addr + "/slow")
+		if err != nil {
+			t.Logf("slow request failed: %v", err)
+			slowResp <- nil
+			return
+		}
+		slowResp <- resp
+	}()
+
+	// Give the slow request time to reach the handler before we signal shutdown.
+	time.Sleep(100 * time.Millisecond)
+	shutdownStart := time.Now()
+	sigCh <- os.Interrupt
+
+	// Give beginDraining time to flip the flag before we probe /healthz.
+	// This is still well inside drainDelay, so the listener must still be open.
+	time.Sleep(50 * time.Millisecond)
+	healthResp, err := http.Get("http://" + addr + "/healthz")
+	if err != nil {
+		t.Fatalf("could not reach /healthz while draining: %v", err)
+	}
+	if healthResp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("got /healthz status %d while draining, want %d", healthResp.StatusCode, http.StatusServiceUnavailable)
+	}
+	if elapsed := time.Since(shutdownStart); elapsed >= drainDelay {
+		t.Fatalf("probed /healthz %v after signal, which is not before drainDelay (%v) elapsed", elapsed, drainDelay)
+	}
+
+	close(releaseSlowRequest)
+
+	resp := <-slowResp
+	if resp == nil {
+		t.Fatal("slow request did not complete")
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("in-flight request got status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	select {
+	case err := <-runErr:
+		if err != nil {
+			t.Errorf("runServer returned error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("runServer did not shut down within its grace period")
+	}
+	if elapsed := time.Since(shutdownStart); elapsed < drainDelay {
+		t.Errorf("runServer shut down after %v, want at least drainDelay (%v)", elapsed, drainDelay)
+	}
+}
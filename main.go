@@ -1,186 +1,175 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"database/sql"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
 	"strconv"
-	"sync"
+	"syscall"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	clientv3 "go.etcd.io/etcd/client/v3"
 )
 
-type Task struct {
-	ID          int    `json:"id"`
-	Description string `json:"description"`
-	Done        bool   `json:"done"`
-}
-
-var (
-	tasks  = make(map[int]Task)
-	nextID = 1
-	mutex  = &sync.RWMutex{}
+const (
+	defaultShutdownTimeout = 30 * time.Second
+	defaultDrainDelay      = 5 * time.Second
 )
 
-func healthCheckHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
-}
-
-func createTaskHandler(w http.ResponseWriter, r *http.Request) {
-	var taskInput struct {
-		Description string `json:"description"`
-	}
-
-	if err := json.NewDecoder(r.Body).Decode(&taskInput); err != nil {
-		http.Error(w, `{"error": "Invalid request body"}`, http.StatusBadRequest)
-		return
+// newStore selects a TaskStore implementation from the environment.
+// STORE_BACKEND chooses the driver: "memory" (default), "sqlite",
+// "postgres", or "etcd". The sqlite/postgres backends read DATABASE_URL
+// for the connection string; etcd reads ETCD_ENDPOINTS (comma-separated).
+func newStore() (TaskStore, error) {
+	switch backend := os.Getenv("STORE_BACKEND"); backend {
+	case "", "memory":
+		return NewMemoryStore(), nil
+
+	case "sqlite", "postgres":
+		dsn := os.Getenv("DATABASE_URL")
+		driverName := "sqlite3"
+		if backend == "postgres" {
+			driverName = "postgres"
+		}
+		db, err := sql.Open(driverName, dsn)
+		if err != nil {
+			return nil, fmt.Errorf("opening %s database: %w", backend, err)
+		}
+		return NewSQLStore(db, backend)
+
+	case "etcd":
+		endpoints := os.Getenv("ETCD_ENDPOINTS")
+		client, err := clientv3.New(clientv3.Config{
+			Endpoints:   splitCSV(endpoints),
+			DialTimeout: 5 * time.Second,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("connecting to etcd: %w", err)
+		}
+		return NewEtcdStore(client), nil
+
+	default:
+		return nil, fmt.Errorf("unknown STORE_BACKEND %q", backend)
 	}
-	if taskInput.Description == "" {
-		http.Error(w, `{"error": "Missing 'description' in request body"}`, http.StatusBadRequest)
-		return
-	}
-
-	mutex.Lock()
-	defer mutex.Unlock()
-
-	newTask := Task{
-		ID:          nextID,
-		Description: taskInput.Description,
-		Done:        false,
-	}
-	tasks[nextID] = newTask
-	nextID++
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(newTask)
 }
 
-func getTasksHandler(w http.ResponseWriter, r *http.Request) {
-	mutex.RLock()
-	defer mutex.RUnlock()
-
-	taskList := make([]Task, 0, len(tasks))
-	for _, task := range tasks {
-		taskList = append(taskList, task)
+func splitCSV(s string) []string {
+	var out []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == ',' {
+			out = append(out, s[start:i])
+			start = i + 1
+		}
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(taskList)
+	return append(out, s[start:])
 }
 
-func getTaskHandler(w http.ResponseWriter, r *http.Request) {
-	idStr := chi.URLParam(r, "taskID")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		http.Error(w, `{"error": "Invalid task ID"}`, http.StatusBadRequest)
-		return
-	}
-
-	mutex.RLock()
-	task, exists := tasks[id]
-	mutex.RUnlock()
-
-	if !exists {
-		http.Error(w, `{"error": "Task not found"}`, http.StatusNotFound)
-		return
+// shutdownTimeout reads the grace period for draining in-flight requests
+// from SHUTDOWN_TIMEOUT (seconds), defaulting to 30s.
+func shutdownTimeout() time.Duration {
+	if raw := os.Getenv("SHUTDOWN_TIMEOUT"); raw != "" {
+		if secs, err := strconv.Atoi(raw); err == nil {
+			return time.Duration(secs) * time.Second
+		}
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(task)
+	return defaultShutdownTimeout
 }
 
-func updateTaskHandler(w http.ResponseWriter, r *http.Request) {
-	idStr := chi.URLParam(r, "taskID")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		http.Error(w, `{"error": "Invalid task ID"}`, http.StatusBadRequest)
-		return
-	}
-
-	var taskInput struct {
-		Description *string `json:"description"`
-		Done        *bool   `json:"done"`
-	}
-
-	if err := json.NewDecoder(r.Body).Decode(&taskInput); err != nil {
-		http.Error(w, `{"error": "Invalid request body"}`, http.StatusBadRequest)
-		return
-	}
-
-	mutex.Lock()
-	defer mutex.Unlock()
-
-	task, exists := tasks[id]
-	if !exists {
-		http.Error(w, `{"error": "Task not found"}`, http.StatusNotFound)
-		return
-	}
-
-	if taskInput.Description != nil {
-		task.Description = *taskInput.Description
-	}
-	if taskInput.Done != nil {
-		task.Done = *taskInput.Done
-	}
-
-	tasks[id] = task
+// drainDelay reads how long to keep the listener open after beginDraining
+// from DRAIN_DELAY (seconds), defaulting to 5s. This gives a load balancer
+// time to observe /healthz reporting 503 and stop routing new traffic
+// before the listener actually closes.
+func drainDelay() time.Duration {
+	if raw := os.Getenv("DRAIN_DELAY"); raw != "" {
+		if secs, err := strconv.Atoi(raw); err == nil {
+			return time.Duration(secs) * time.Second
+		}
+	}
+	return defaultDrainDelay
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(task)
+// runServer serves httpServer until a signal arrives on sigCh, then drains
+// appServer (flipping /healthz to draining and closing watch streams),
+// keeps the listener open for delay so load balancers can observe the
+// draining state, and shuts httpServer down within grace. It returns any
+// error from serving or from a shutdown that didn't finish in time.
+func runServer(httpServer *http.Server, appServer *Server, grace, delay time.Duration, sigCh <-chan os.Signal) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			serveErr <- err
+			return
+		}
+		serveErr <- nil
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-sigCh:
+	}
+
+	appServer.beginDraining()
+	time.Sleep(delay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), grace)
+	defer cancel()
+	return httpServer.Shutdown(ctx)
 }
 
-func deleteTaskHandler(w http.ResponseWriter, r *http.Request) {
-	idStr := chi.URLParam(r, "taskID")
-	id, err := strconv.Atoi(idStr)
+func main() {
+	store, err := newStore()
 	if err != nil {
-		http.Error(w, `{"error": "Invalid task ID"}`, http.StatusBadRequest)
-		return
+		log.Fatalf("Could not initialize task store: %s\n", err)
 	}
+	srv := NewServer(store)
+	srv.requirePreconditions = os.Getenv("REQUIRE_CONDITIONAL_HEADERS") == "true"
 
-	mutex.Lock()
-	_, exists := tasks[id]
-	if exists {
-		delete(tasks, id)
+	if err := seedTasksTotal(context.Background(), store); err != nil {
+		log.Fatalf("Could not seed tasks_total metric: %s\n", err)
 	}
-	mutex.Unlock()
 
-	if !exists {
-		http.Error(w, `{"error": "Task not found"}`, http.StatusNotFound)
-		return
-	}
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 
-	w.WriteHeader(http.StatusNoContent)
-}
-
-func main() {
 	r := chi.NewRouter()
 
-	r.Use(middleware.Logger)
+	r.Use(requestLoggingMiddleware(logger))
+	r.Use(metricsMiddleware)
 	r.Use(middleware.Recoverer)
 
-	r.Get("/healthz", healthCheckHandler)
+	r.Get("/healthz", srv.healthCheckHandler)
+	r.Get("/metrics", metricsHandler().ServeHTTP)
 
 	r.Route("/tasks", func(r chi.Router) {
-		r.Post("/", createTaskHandler)
-		r.Get("/", getTasksHandler)
-		r.Get("/{taskID}", getTaskHandler)
-		r.Put("/{taskID}", updateTaskHandler)
-		r.Delete("/{taskID}", deleteTaskHandler)
+		r.Post("/", srv.createTaskHandler)
+		r.Get("/", srv.getTasksHandler)
+		r.Get("/watch", srv.watchTasksHandler)
+		r.Get("/{taskID}", srv.getTaskHandler)
+		r.Put("/{taskID}", srv.updateTaskHandler)
+		r.Patch("/{taskID}", srv.patchTaskHandler)
+		r.Delete("/{taskID}", srv.deleteTaskHandler)
 	})
 
 	port := "8080"
-	log.Printf("Starting server on port %s\n", port)
+	httpServer := &http.Server{
+		Addr:    fmt.Sprintf(":%s", port),
+		Handler: r,
+	}
 
-	err := http.ListenAndServe(fmt.Sprintf(":%s", port), r)
-	if err != nil {
-		log.Fatalf("Could not start server: %s\n", err)
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+
+	log.Printf("Starting server on port %s\n", port)
+	if err := runServer(httpServer, srv, shutdownTimeout(), drainDelay(), sigCh); err != nil {
+		log.Fatalf("Server error: %s\n", err)
 	}
+	log.Println("Server shut down cleanly")
 }
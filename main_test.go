@@ -13,21 +13,20 @@ import (
 	"github.com/go-chi/chi/v5"
 )
 
-func resetGlobalState() {
-	mutex.Lock()
-	defer mutex.Unlock()
-	tasks = make(map[int]Task)
-	nextID = 1
+func newTestServer() *Server {
+	return NewServer(NewMemoryStore())
 }
 
 func TestHealthCheckHandler(t *testing.T) {
+	srv := newTestServer()
+
 	req, err := http.NewRequest("GET", "/healthz", nil)
 	if err != nil {
 		t.Fatalf("Could not create request: %v", err)
 	}
 
 	rr := httptest.NewRecorder()
-	healthCheckHandler(rr, req)
+	srv.healthCheckHandler(rr, req)
 
 	if status := rr.Code; status != http.StatusOK {
 		t.Errorf("handler returned wrong status code: got %v want %v",
@@ -48,7 +47,7 @@ func TestHealthCheckHandler(t *testing.T) {
 }
 
 func TestCreateTaskHandler(t *testing.T) {
-	resetGlobalState()
+	srv := newTestServer()
 
 	jsonData := `{"description": "Minha Tarefa de Teste"}`
 	reqBody := bytes.NewBufferString(jsonData)
@@ -60,7 +59,7 @@ func TestCreateTaskHandler(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 
 	rr := httptest.NewRecorder()
-	handler := http.HandlerFunc(createTaskHandler)
+	handler := http.HandlerFunc(srv.createTaskHandler)
 
 	handler.ServeHTTP(rr, req)
 
@@ -88,16 +87,13 @@ func TestCreateTaskHandler(t *testing.T) {
 			createdTask.Done, false)
 	}
 
-	mutex.RLock()
-	_, exists := tasks[1]
-	mutex.RUnlock()
-	if !exists {
-		t.Errorf("task was not added to the global tasks map")
+	if _, err := srv.store.Get(context.Background(), 1); err != nil {
+		t.Errorf("task was not added to the store: %v", err)
 	}
 }
 
 func TestCreateTaskHandler_BadRequest(t *testing.T) {
-	resetGlobalState()
+	srv := newTestServer()
 
 	jsonData := `{}`
 	reqBody := bytes.NewBufferString(jsonData)
@@ -105,7 +101,7 @@ func TestCreateTaskHandler_BadRequest(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 
 	rr := httptest.NewRecorder()
-	handler := http.HandlerFunc(createTaskHandler)
+	handler := http.HandlerFunc(srv.createTaskHandler)
 	handler.ServeHTTP(rr, req)
 
 	if status := rr.Code; status != http.StatusBadRequest {
@@ -115,11 +111,11 @@ func TestCreateTaskHandler_BadRequest(t *testing.T) {
 }
 
 func TestGetTasksHandler(t *testing.T) {
-	resetGlobalState()
+	srv := newTestServer()
 
 	req, _ := http.NewRequest("GET", "/tasks", nil)
 	rr := httptest.NewRecorder()
-	handler := http.HandlerFunc(getTasksHandler)
+	handler := http.HandlerFunc(srv.getTasksHandler)
 	handler.ServeHTTP(rr, req)
 
 	if status := rr.Code; status != http.StatusOK {
@@ -129,12 +125,11 @@ func TestGetTasksHandler(t *testing.T) {
 		t.Errorf("handler returned wrong body for empty list: got %v want %v", body, "[]")
 	}
 
-	resetGlobalState()
-	mutex.Lock()
-	tasks[1] = Task{ID: 1, Description: "Tarefa 1", Done: false}
-	tasks[2] = Task{ID: 2, Description: "Tarefa 2", Done: true}
-	nextID = 3
-	mutex.Unlock()
+	ctx := context.Background()
+	srv.store.Create(ctx, "Tarefa 1")
+	createdDone, _ := srv.store.Create(ctx, "Tarefa 2")
+	done := true
+	srv.store.Update(ctx, createdDone.ID, nil, &done, 0)
 
 	req, _ = http.NewRequest("GET", "/tasks", nil)
 	rr = httptest.NewRecorder()
@@ -170,11 +165,8 @@ func newRequestWithChiContext(method, target string, body io.Reader, params map[
 }
 
 func TestGetTaskHandler(t *testing.T) {
-	resetGlobalState()
-	mutex.Lock()
-	tasks[1] = Task{ID: 1, Description: "Buscar esta tarefa", Done: false}
-	nextID = 2
-	mutex.Unlock()
+	srv := newTestServer()
+	srv.store.Create(context.Background(), "Buscar esta tarefa")
 
 	req, err := newRequestWithChiContext("GET", "/tasks/1", nil, map[string]string{"taskID": "1"})
 	if err != nil {
@@ -182,7 +174,7 @@ func TestGetTaskHandler(t *testing.T) {
 	}
 
 	rr := httptest.NewRecorder()
-	handler := http.HandlerFunc(getTaskHandler)
+	handler := http.HandlerFunc(srv.getTaskHandler)
 	handler.ServeHTTP(rr, req)
 
 	if status := rr.Code; status != http.StatusOK {
@@ -215,11 +207,8 @@ func TestGetTaskHandler(t *testing.T) {
 }
 
 func TestUpdateTaskHandler(t *testing.T) {
-	resetGlobalState()
-	mutex.Lock()
-	tasks[1] = Task{ID: 1, Description: "Original", Done: false}
-	nextID = 2
-	mutex.Unlock()
+	srv := newTestServer()
+	srv.store.Create(context.Background(), "Original")
 
 	updateData := `{"description": "Atualizada", "done": true}`
 	reqBody := bytes.NewBufferString(updateData)
@@ -230,7 +219,7 @@ func TestUpdateTaskHandler(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 
 	rr := httptest.NewRecorder()
-	handler := http.HandlerFunc(updateTaskHandler)
+	handler := http.HandlerFunc(srv.updateTaskHandler)
 	handler.ServeHTTP(rr, req)
 
 	if status := rr.Code; status != http.StatusOK {
@@ -245,11 +234,9 @@ func TestUpdateTaskHandler(t *testing.T) {
 		t.Errorf("handler returned wrong updated task data: got %+v", updatedTask)
 	}
 
-	mutex.RLock()
-	taskInMap := tasks[1]
-	mutex.RUnlock()
-	if taskInMap.Description != "Atualizada" || !taskInMap.Done {
-		t.Errorf("task data in global map was not updated correctly: got %+v", taskInMap)
+	taskInMap, err := srv.store.Get(context.Background(), 1)
+	if err != nil || taskInMap.Description != "Atualizada" || !taskInMap.Done {
+		t.Errorf("task data in store was not updated correctly: got %+v (err=%v)", taskInMap, err)
 	}
 
 	updateData = `{"description": "Não importa"}`
@@ -264,11 +251,8 @@ func TestUpdateTaskHandler(t *testing.T) {
 }
 
 func TestDeleteTaskHandler(t *testing.T) {
-	resetGlobalState()
-	mutex.Lock()
-	tasks[1] = Task{ID: 1, Description: "Para Deletar", Done: false}
-	nextID = 2
-	mutex.Unlock()
+	srv := newTestServer()
+	srv.store.Create(context.Background(), "Para Deletar")
 
 	req, err := newRequestWithChiContext("DELETE", "/tasks/1", nil, map[string]string{"taskID": "1"})
 	if err != nil {
@@ -276,18 +260,15 @@ func TestDeleteTaskHandler(t *testing.T) {
 	}
 
 	rr := httptest.NewRecorder()
-	handler := http.HandlerFunc(deleteTaskHandler)
+	handler := http.HandlerFunc(srv.deleteTaskHandler)
 	handler.ServeHTTP(rr, req)
 
 	if status := rr.Code; status != http.StatusNoContent {
 		t.Errorf("handler returned wrong status code for delete success: got %v want %v", status, http.StatusNoContent)
 	}
 
-	mutex.RLock()
-	_, exists := tasks[1]
-	mutex.RUnlock()
-	if exists {
-		t.Errorf("task was not deleted from the global tasks map")
+	if _, err := srv.store.Get(context.Background(), 1); err == nil {
+		t.Errorf("task was not deleted from the store")
 	}
 
 	req, _ = newRequestWithChiContext("DELETE", "/tasks/99", nil, map[string]string{"taskID": "99"})
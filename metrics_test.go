@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func newMetricsTestRouter(srv *Server) http.Handler {
+	r := chi.NewRouter()
+	r.Use(metricsMiddleware)
+	r.Get("/metrics", metricsHandler().ServeHTTP)
+	r.Route("/tasks", func(r chi.Router) {
+		r.Post("/", srv.createTaskHandler)
+		r.Get("/{taskID}", srv.getTaskHandler)
+	})
+	return r
+}
+
+func TestMetricsMiddleware_CountsRequests(t *testing.T) {
+	srv := newTestServer()
+	ts := httptest.NewServer(newMetricsTestRouter(srv))
+	defer ts.Close()
+
+	before := testutil.ToFloat64(httpRequestsTotal.WithLabelValues("GET", "/tasks/{taskID}", "404"))
+
+	resp, err := http.Get(ts.URL + "/tasks/999")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+
+	after := testutil.ToFloat64(httpRequestsTotal.WithLabelValues("GET", "/tasks/{taskID}", "404"))
+	if after != before+1 {
+		t.Errorf("http_requests_total did not increment: before=%v after=%v", before, after)
+	}
+
+	metricsResp, err := http.Get(ts.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("could not scrape /metrics: %v", err)
+	}
+	defer metricsResp.Body.Close()
+
+	body, err := io.ReadAll(metricsResp.Body)
+	if err != nil {
+		t.Fatalf("could not read /metrics body: %v", err)
+	}
+	if !strings.Contains(string(body), "http_requests_total") {
+		t.Errorf("/metrics output did not contain http_requests_total")
+	}
+}
+
+func TestSeedTasksTotal_ReflectsExistingStoreContents(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	store.Create(ctx, "pre-existing one")
+	store.Create(ctx, "pre-existing two")
+
+	if err := seedTasksTotal(ctx, store); err != nil {
+		t.Fatalf("seedTasksTotal failed: %v", err)
+	}
+	if got := testutil.ToFloat64(tasksTotal); got != 2 {
+		t.Errorf("got tasks_total %v, want %v", got, 2)
+	}
+}
+
+func TestMetricsMiddleware_TaskOperationsCounter(t *testing.T) {
+	srv := newTestServer()
+	ts := httptest.NewServer(newMetricsTestRouter(srv))
+	defer ts.Close()
+
+	before := testutil.ToFloat64(taskOperationsTotal.WithLabelValues("create"))
+
+	resp, err := http.Post(ts.URL+"/tasks/", "application/json", strings.NewReader(`{"description": "counted"}`))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	after := testutil.ToFloat64(taskOperationsTotal.WithLabelValues("create"))
+	if after != before+1 {
+		t.Errorf("task_operations_total{op=create} did not increment: before=%v after=%v", before, after)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const defaultListLimit = 50
+
+// listQuery holds the parsed query parameters accepted by GET /tasks.
+type listQuery struct {
+	limit int
+	after int // task ID to resume after, decoded from the cursor; 0 means start at the beginning
+	done  *bool
+	q     string
+	sort  string // one of "id", "-id", "description"
+}
+
+// parseListQuery parses and validates the query string for GET /tasks.
+func parseListQuery(values map[string][]string) (listQuery, error) {
+	query := listQuery{limit: defaultListLimit, sort: "id"}
+
+	if raw := first(values, "limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			return listQuery{}, fmt.Errorf("invalid 'limit' query parameter %q", raw)
+		}
+		query.limit = limit
+	}
+
+	if raw := first(values, "cursor"); raw != "" {
+		after, err := decodeCursor(raw)
+		if err != nil {
+			return listQuery{}, fmt.Errorf("invalid 'cursor' query parameter: %w", err)
+		}
+		query.after = after
+	}
+
+	if raw := first(values, "done"); raw != "" {
+		done, err := strconv.ParseBool(raw)
+		if err != nil {
+			return listQuery{}, fmt.Errorf("invalid 'done' query parameter %q", raw)
+		}
+		query.done = &done
+	}
+
+	query.q = first(values, "q")
+
+	if raw := first(values, "sort"); raw != "" {
+		switch raw {
+		case "id", "-id", "description":
+			query.sort = raw
+		default:
+			return listQuery{}, fmt.Errorf("invalid 'sort' query parameter %q", raw)
+		}
+	}
+
+	return query, nil
+}
+
+func first(values map[string][]string, key string) string {
+	v := values[key]
+	if len(v) == 0 {
+		return ""
+	}
+	return v[0]
+}
+
+func encodeCursor(id int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(id)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(string(raw))
+}
+
+// applyListQuery filters, sorts, and paginates tasks per query, returning
+// the page, the total number of tasks matching the filter (regardless of
+// pagination), and whether a next page exists.
+func applyListQuery(tasks []Task, query listQuery) (page []Task, total int, hasNext bool) {
+	filtered := make([]Task, 0, len(tasks))
+	for _, task := range tasks {
+		if query.done != nil && task.Done != *query.done {
+			continue
+		}
+		if query.q != "" && !strings.Contains(task.Description, query.q) {
+			continue
+		}
+		filtered = append(filtered, task)
+	}
+
+	switch query.sort {
+	case "-id":
+		sort.Slice(filtered, func(i, j int) bool { return filtered[i].ID > filtered[j].ID })
+	case "description":
+		sort.Slice(filtered, func(i, j int) bool { return filtered[i].Description < filtered[j].Description })
+	default:
+		sort.Slice(filtered, func(i, j int) bool { return filtered[i].ID < filtered[j].ID })
+	}
+
+	start := 0
+	if query.after != 0 {
+		start = len(filtered)
+		for i, task := range filtered {
+			if task.ID == query.after {
+				start = i + 1
+				break
+			}
+		}
+	}
+	if start >= len(filtered) {
+		return []Task{}, len(filtered), false
+	}
+
+	end := start + query.limit
+	if end >= len(filtered) {
+		return filtered[start:], len(filtered), false
+	}
+	return filtered[start:end], len(filtered), true
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetTasksHandler_EmptyList(t *testing.T) {
+	srv := newTestServer()
+
+	req, _ := http.NewRequest("GET", "/tasks", nil)
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(srv.getTasksHandler).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", rr.Code, http.StatusOK)
+	}
+	if count := rr.Header().Get("X-Total-Count"); count != "0" {
+		t.Errorf("got X-Total-Count %q, want %q", count, "0")
+	}
+	if rr.Header().Get("Link") != "" {
+		t.Errorf("did not expect a Link header for an empty list")
+	}
+}
+
+func TestGetTasksHandler_InvalidLimit(t *testing.T) {
+	srv := newTestServer()
+
+	req, _ := http.NewRequest("GET", "/tasks?limit=not-a-number", nil)
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(srv.getTasksHandler).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("got status %d, want %d", rr.Code, http.StatusBadRequest)
+	}
+}
+
+func TestGetTasksHandler_PaginationAndCursorExhaustion(t *testing.T) {
+	srv := newTestServer()
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		srv.store.Create(ctx, "task")
+	}
+
+	req, _ := http.NewRequest("GET", "/tasks?limit=2", nil)
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(srv.getTasksHandler).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", rr.Code, http.StatusOK)
+	}
+	if rr.Header().Get("X-Total-Count") != "3" {
+		t.Errorf("got X-Total-Count %q, want %q", rr.Header().Get("X-Total-Count"), "3")
+	}
+	link := rr.Header().Get("Link")
+	if link == "" {
+		t.Fatalf("expected a Link header when more pages exist")
+	}
+
+	var firstPage []Task
+	json.NewDecoder(rr.Body).Decode(&firstPage)
+	if len(firstPage) != 2 {
+		t.Fatalf("got %d tasks in first page, want 2", len(firstPage))
+	}
+
+	cursor := encodeCursor(firstPage[len(firstPage)-1].ID)
+	req, _ = http.NewRequest("GET", "/tasks?limit=2&cursor="+cursor, nil)
+	rr = httptest.NewRecorder()
+	http.HandlerFunc(srv.getTasksHandler).ServeHTTP(rr, req)
+
+	var secondPage []Task
+	json.NewDecoder(rr.Body).Decode(&secondPage)
+	if len(secondPage) != 1 {
+		t.Fatalf("got %d tasks in second page, want 1", len(secondPage))
+	}
+	if rr.Header().Get("Link") != "" {
+		t.Errorf("did not expect a Link header on the last page")
+	}
+
+	// An exhausted cursor (past the last task) should return an empty page.
+	lastID := secondPage[len(secondPage)-1].ID
+	req, _ = http.NewRequest("GET", "/tasks?cursor="+encodeCursor(lastID), nil)
+	rr = httptest.NewRecorder()
+	http.HandlerFunc(srv.getTasksHandler).ServeHTTP(rr, req)
+
+	var exhausted []Task
+	json.NewDecoder(rr.Body).Decode(&exhausted)
+	if len(exhausted) != 0 {
+		t.Errorf("got %d tasks past the last cursor, want 0", len(exhausted))
+	}
+}
+
+func TestGetTasksHandler_FilterAndSort(t *testing.T) {
+	srv := newTestServer()
+	ctx := context.Background()
+	srv.store.Create(ctx, "banana")
+	done := true
+	created, _ := srv.store.Create(ctx, "apple")
+	srv.store.Update(ctx, created.ID, nil, &done, 0)
+
+	req, _ := http.NewRequest("GET", "/tasks?done=true&sort=description", nil)
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(srv.getTasksHandler).ServeHTTP(rr, req)
+
+	var tasks []Task
+	json.NewDecoder(rr.Body).Decode(&tasks)
+	if len(tasks) != 1 || tasks[0].Description != "apple" {
+		t.Errorf("got %+v, want a single task with description 'apple'", tasks)
+	}
+	if rr.Header().Get("X-Total-Count") != "1" {
+		t.Errorf("got X-Total-Count %q, want %q for a filtered query", rr.Header().Get("X-Total-Count"), "1")
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// errPatchTest signals that a JSON Patch "test" op failed its comparison,
+// which the caller maps to 409 Conflict rather than 400 Bad Request.
+var errPatchTest = errors.New("patch test operation failed")
+
+// applyMergePatch implements RFC 7396: patch is recursively merged into doc,
+// and a JSON null in patch deletes the corresponding key. Modifying "id" is
+// rejected.
+func applyMergePatch(doc map[string]any, patch map[string]any) error {
+	if _, ok := patch["id"]; ok {
+		return fmt.Errorf("merge patch must not modify \"id\"")
+	}
+	mergeInto(doc, patch)
+	return nil
+}
+
+func mergeInto(doc, patch map[string]any) {
+	for key, patchVal := range patch {
+		if patchVal == nil {
+			delete(doc, key)
+			continue
+		}
+
+		patchObj, patchIsObj := patchVal.(map[string]any)
+		docObj, docIsObj := doc[key].(map[string]any)
+		if patchIsObj && docIsObj {
+			mergeInto(docObj, patchObj)
+			continue
+		}
+
+		doc[key] = patchVal
+	}
+}
+
+// jsonPatchOp is one operation in an RFC 6902 JSON Patch document.
+type jsonPatchOp struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	From  string `json:"from"`
+	Value any    `json:"value"`
+}
+
+// applyJSONPatch implements RFC 6902 against doc, mutating it in place.
+// It rejects any op that touches "/id".
+func applyJSONPatch(doc map[string]any, ops []jsonPatchOp) error {
+	for _, op := range ops {
+		if op.Path == "/id" || op.From == "/id" {
+			return fmt.Errorf("json patch must not modify \"id\"")
+		}
+
+		switch op.Op {
+		case "add", "replace":
+			if err := setJSONPointer(doc, op.Path, op.Value); err != nil {
+				return err
+			}
+		case "remove":
+			if err := removeJSONPointer(doc, op.Path); err != nil {
+				return err
+			}
+		case "move":
+			val, err := getJSONPointer(doc, op.From)
+			if err != nil {
+				return err
+			}
+			if err := removeJSONPointer(doc, op.From); err != nil {
+				return err
+			}
+			if err := setJSONPointer(doc, op.Path, val); err != nil {
+				return err
+			}
+		case "copy":
+			val, err := getJSONPointer(doc, op.From)
+			if err != nil {
+				return err
+			}
+			if err := setJSONPointer(doc, op.Path, val); err != nil {
+				return err
+			}
+		case "test":
+			val, err := getJSONPointer(doc, op.Path)
+			if err != nil {
+				return err
+			}
+			valJSON, _ := json.Marshal(val)
+			wantJSON, _ := json.Marshal(op.Value)
+			if string(valJSON) != string(wantJSON) {
+				return errPatchTest
+			}
+		default:
+			return fmt.Errorf("unknown json patch op %q", op.Op)
+		}
+	}
+	return nil
+}
+
+// splitJSONPointer splits a JSON Pointer ("/a/b~1c") into unescaped tokens.
+func splitJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid json pointer %q", pointer)
+	}
+	parts := strings.Split(pointer[1:], "/")
+	for i, part := range parts {
+		part = strings.ReplaceAll(part, "~1", "/")
+		part = strings.ReplaceAll(part, "~0", "~")
+		parts[i] = part
+	}
+	return parts, nil
+}
+
+func getJSONPointer(doc map[string]any, pointer string) (any, error) {
+	tokens, err := splitJSONPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return doc, nil
+	}
+
+	var cur any = doc
+	for _, tok := range tokens {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("path %q does not exist", pointer)
+		}
+		cur, ok = m[tok]
+		if !ok {
+			return nil, fmt.Errorf("path %q does not exist", pointer)
+		}
+	}
+	return cur, nil
+}
+
+func setJSONPointer(doc map[string]any, pointer string, value any) error {
+	tokens, err := splitJSONPointer(pointer)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return fmt.Errorf("cannot replace document root")
+	}
+
+	m := doc
+	for _, tok := range tokens[:len(tokens)-1] {
+		next, ok := m[tok].(map[string]any)
+		if !ok {
+			return fmt.Errorf("path %q does not exist", pointer)
+		}
+		m = next
+	}
+	m[tokens[len(tokens)-1]] = value
+	return nil
+}
+
+func removeJSONPointer(doc map[string]any, pointer string) error {
+	tokens, err := splitJSONPointer(pointer)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return fmt.Errorf("cannot remove document root")
+	}
+
+	m := doc
+	for _, tok := range tokens[:len(tokens)-1] {
+		next, ok := m[tok].(map[string]any)
+		if !ok {
+			return fmt.Errorf("path %q does not exist", pointer)
+		}
+		m = next
+	}
+	key := tokens[len(tokens)-1]
+	if _, ok := m[key]; !ok {
+		return fmt.Errorf("path %q does not exist", pointer)
+	}
+	delete(m, key)
+	return nil
+}
+
+func taskToMap(task Task) (map[string]any, error) {
+	body, err := json.Marshal(task)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func mapToTask(doc map[string]any) (Task, error) {
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return Task{}, err
+	}
+	var task Task
+	if err := json.Unmarshal(body, &task); err != nil {
+		return Task{}, err
+	}
+	return task, nil
+}
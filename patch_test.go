@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPatchTaskHandler_MergePatch(t *testing.T) {
+	srv := newTestServer()
+	srv.store.Create(context.Background(), "Original")
+
+	body := bytes.NewBufferString(`{"done": true}`)
+	req, _ := newRequestWithChiContext("PATCH", "/tasks/1", body, map[string]string{"taskID": "1"})
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(srv.patchTaskHandler).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("got status %d, want %d: %s", rr.Code, http.StatusAccepted, rr.Body.String())
+	}
+
+	var task Task
+	if err := json.NewDecoder(rr.Body).Decode(&task); err != nil {
+		t.Fatalf("could not decode response: %v", err)
+	}
+	if !task.Done {
+		t.Errorf("merge patch did not apply done=true: %+v", task)
+	}
+	if rr.Header().Get("ETag") == "" {
+		t.Errorf("expected an ETag header on a successful patch")
+	}
+	if rr.Header().Get("Last-Modified") == "" {
+		t.Errorf("expected a Last-Modified header on a successful patch")
+	}
+}
+
+func TestPatchTaskHandler_JSONPatch(t *testing.T) {
+	srv := newTestServer()
+	srv.store.Create(context.Background(), "Original")
+
+	body := bytes.NewBufferString(`[
+		{"op": "test", "path": "/done", "value": false},
+		{"op": "replace", "path": "/description", "value": "Patched"}
+	]`)
+	req, _ := newRequestWithChiContext("PATCH", "/tasks/1", body, map[string]string{"taskID": "1"})
+	req.Header.Set("Content-Type", "application/json-patch+json")
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(srv.patchTaskHandler).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("got status %d, want %d: %s", rr.Code, http.StatusAccepted, rr.Body.String())
+	}
+
+	var task Task
+	if err := json.NewDecoder(rr.Body).Decode(&task); err != nil {
+		t.Fatalf("could not decode response: %v", err)
+	}
+	if task.Description != "Patched" {
+		t.Errorf("json patch did not apply replace: %+v", task)
+	}
+}
+
+func TestPatchTaskHandler_JSONPatchFailedTest(t *testing.T) {
+	srv := newTestServer()
+	srv.store.Create(context.Background(), "Original")
+
+	body := bytes.NewBufferString(`[{"op": "test", "path": "/done", "value": true}]`)
+	req, _ := newRequestWithChiContext("PATCH", "/tasks/1", body, map[string]string{"taskID": "1"})
+	req.Header.Set("Content-Type", "application/json-patch+json")
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(srv.patchTaskHandler).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusConflict {
+		t.Errorf("got status %d, want %d", rr.Code, http.StatusConflict)
+	}
+}
+
+func TestPatchTaskHandler_IfMatchConflict(t *testing.T) {
+	srv := newTestServer()
+	srv.store.Create(context.Background(), "Original")
+
+	body := bytes.NewBufferString(`{"done": true}`)
+	req, _ := newRequestWithChiContext("PATCH", "/tasks/1", body, map[string]string{"taskID": "1"})
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("If-Match", weakETag(999))
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(srv.patchTaskHandler).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusPreconditionFailed {
+		t.Errorf("got status %d, want %d", rr.Code, http.StatusPreconditionFailed)
+	}
+}
+
+func TestPatchTaskHandler_UnknownContentType(t *testing.T) {
+	srv := newTestServer()
+	srv.store.Create(context.Background(), "Original")
+
+	body := bytes.NewBufferString(`{}`)
+	req, _ := newRequestWithChiContext("PATCH", "/tasks/1", body, map[string]string{"taskID": "1"})
+	req.Header.Set("Content-Type", "text/plain")
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(srv.patchTaskHandler).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("got status %d, want %d", rr.Code, http.StatusUnsupportedMediaType)
+	}
+}
+
+func TestApplyMergePatch_RejectsIDChange(t *testing.T) {
+	doc := map[string]any{"id": float64(1), "description": "x"}
+	patch := map[string]any{"id": float64(2)}
+
+	if err := applyMergePatch(doc, patch); err == nil {
+		t.Errorf("expected error when merge patch modifies id")
+	}
+}
+
+func TestApplyJSONPatch_RejectsIDChange(t *testing.T) {
+	doc := map[string]any{"id": float64(1), "description": "x"}
+	ops := []jsonPatchOp{{Op: "replace", Path: "/id", Value: float64(2)}}
+
+	if err := applyJSONPatch(doc, ops); err == nil {
+		t.Errorf("expected error when json patch modifies id")
+	}
+}
@@ -0,0 +1,386 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Server holds the shared dependencies for the HTTP handlers. A *Server
+// method set is mounted onto the chi router in main; tests construct their
+// own Server around a MemoryStore instead of relying on package globals.
+type Server struct {
+	store TaskStore
+
+	// requirePreconditions, when true, rejects writes to /tasks/{taskID}
+	// that carry neither If-Match nor If-Unmodified-Since with 428
+	// Precondition Required.
+	requirePreconditions bool
+
+	draining     int32 // set atomically; 1 once the server is shutting down
+	drainOnce    sync.Once
+	shuttingDown chan struct{}
+}
+
+// NewServer returns a Server backed by store.
+func NewServer(store TaskStore) *Server {
+	return &Server{store: store, shuttingDown: make(chan struct{})}
+}
+
+// beginDraining flips the server into its draining state: /healthz starts
+// reporting 503 and any open /tasks/watch streams are asked to close so
+// http.Server.Shutdown's wait for in-flight requests can complete.
+func (s *Server) beginDraining() {
+	s.drainOnce.Do(func() {
+		atomic.StoreInt32(&s.draining, 1)
+		close(s.shuttingDown)
+	})
+}
+
+func (s *Server) isDraining() bool {
+	return atomic.LoadInt32(&s.draining) == 1
+}
+
+func (s *Server) healthCheckHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if s.isDraining() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]string{"status": "draining"})
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+func (s *Server) createTaskHandler(w http.ResponseWriter, r *http.Request) {
+	var taskInput struct {
+		Description string `json:"description"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&taskInput); err != nil {
+		http.Error(w, `{"error": "Invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	if taskInput.Description == "" {
+		http.Error(w, `{"error": "Missing 'description' in request body"}`, http.StatusBadRequest)
+		return
+	}
+
+	newTask, err := s.store.Create(r.Context(), taskInput.Description)
+	if err != nil {
+		http.Error(w, `{"error": "Could not create task"}`, http.StatusInternalServerError)
+		return
+	}
+	tasksTotal.Inc()
+	taskOperationsTotal.WithLabelValues("create").Inc()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(newTask)
+}
+
+func (s *Server) getTasksHandler(w http.ResponseWriter, r *http.Request) {
+	query, err := parseListQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+
+	all, err := s.store.List(r.Context())
+	if err != nil {
+		http.Error(w, `{"error": "Could not list tasks"}`, http.StatusInternalServerError)
+		return
+	}
+
+	page, total, hasNext := applyListQuery(all, query)
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	if hasNext {
+		next := page[len(page)-1].ID
+		link := fmt.Sprintf(`<%s?%s>; rel="next"`, r.URL.Path, nextPageQuery(r.URL.Query(), next))
+		w.Header().Set("Link", link)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(page)
+}
+
+// nextPageQuery rebuilds the query string for the next page's Link header,
+// replacing any existing cursor with one pointing past lastID.
+func nextPageQuery(values url.Values, lastID int) string {
+	next := url.Values{}
+	for key, vals := range values {
+		if key == "cursor" {
+			continue
+		}
+		next[key] = vals
+	}
+	next.Set("cursor", encodeCursor(lastID))
+	return next.Encode()
+}
+
+func (s *Server) getTaskHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseTaskID(r)
+	if err != nil {
+		http.Error(w, `{"error": "Invalid task ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	task, err := s.store.Get(r.Context(), id)
+	if errors.Is(err, ErrNotFound) {
+		http.Error(w, `{"error": "Task not found"}`, http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, `{"error": "Could not fetch task"}`, http.StatusInternalServerError)
+		return
+	}
+
+	setTaskHeaders(w, task)
+
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" && matchesETagList(ifNoneMatch, task.Version) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(task)
+}
+
+func (s *Server) updateTaskHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseTaskID(r)
+	if err != nil {
+		http.Error(w, `{"error": "Invalid task ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	var taskInput struct {
+		Description *string `json:"description"`
+		Done        *bool   `json:"done"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&taskInput); err != nil {
+		http.Error(w, `{"error": "Invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+
+	current, err := s.store.Get(r.Context(), id)
+	if errors.Is(err, ErrNotFound) {
+		http.Error(w, `{"error": "Task not found"}`, http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, `{"error": "Could not update task"}`, http.StatusInternalServerError)
+		return
+	}
+
+	expectedVersion, status := s.checkPreconditions(w, r, current)
+	if status != 0 {
+		writePreconditionError(w, status)
+		return
+	}
+
+	task, err := s.store.Update(r.Context(), id, taskInput.Description, taskInput.Done, expectedVersion)
+	if errors.Is(err, ErrNotFound) {
+		http.Error(w, `{"error": "Task not found"}`, http.StatusNotFound)
+		return
+	} else if errors.Is(err, ErrConflict) {
+		http.Error(w, `{"error": "Task was modified concurrently"}`, http.StatusPreconditionFailed)
+		return
+	} else if err != nil {
+		http.Error(w, `{"error": "Could not update task"}`, http.StatusInternalServerError)
+		return
+	}
+	taskOperationsTotal.WithLabelValues("update").Inc()
+
+	setTaskHeaders(w, task)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(task)
+}
+
+// checkPreconditions validates If-Match/If-Unmodified-Since against
+// current and returns the version a write should pass to the store as its
+// expectedVersion (0 when no precondition was given and none is required).
+// A non-zero status means the caller must abort the request with that code.
+func (s *Server) checkPreconditions(w http.ResponseWriter, r *http.Request, current Task) (expectedVersion int64, status int) {
+	ok, status := checkWritePreconditions(r, current, s.requirePreconditions)
+	if !ok {
+		return 0, status
+	}
+	if r.Header.Get("If-Match") != "" || r.Header.Get("If-Unmodified-Since") != "" {
+		return current.Version, 0
+	}
+	return 0, 0
+}
+
+func writePreconditionError(w http.ResponseWriter, status int) {
+	switch status {
+	case http.StatusPreconditionFailed:
+		http.Error(w, `{"error": "Precondition failed"}`, http.StatusPreconditionFailed)
+	case http.StatusPreconditionRequired:
+		http.Error(w, `{"error": "If-Match or If-Unmodified-Since header is required"}`, http.StatusPreconditionRequired)
+	default:
+		http.Error(w, `{"error": "Invalid conditional request header"}`, http.StatusBadRequest)
+	}
+}
+
+// patchTaskHandler implements PATCH /tasks/{taskID}, dispatching on
+// Content-Type between RFC 7396 JSON Merge Patch and RFC 6902 JSON Patch.
+func (s *Server) patchTaskHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseTaskID(r)
+	if err != nil {
+		http.Error(w, `{"error": "Invalid task ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, `{"error": "Could not read request body"}`, http.StatusBadRequest)
+		return
+	}
+
+	current, err := s.store.Get(r.Context(), id)
+	if errors.Is(err, ErrNotFound) {
+		http.Error(w, `{"error": "Task not found"}`, http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, `{"error": "Could not fetch task"}`, http.StatusInternalServerError)
+		return
+	}
+
+	expectedVersion, status := s.checkPreconditions(w, r, current)
+	if status != 0 {
+		writePreconditionError(w, status)
+		return
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	var mutate func(*Task) error
+
+	switch contentType {
+	case "application/merge-patch+json":
+		var patch map[string]any
+		if err := json.Unmarshal(body, &patch); err != nil {
+			http.Error(w, `{"error": "Invalid merge patch body"}`, http.StatusBadRequest)
+			return
+		}
+		mutate = func(t *Task) error {
+			doc, err := taskToMap(*t)
+			if err != nil {
+				return err
+			}
+			if err := applyMergePatch(doc, patch); err != nil {
+				return err
+			}
+			merged, err := mapToTask(doc)
+			if err != nil {
+				return err
+			}
+			merged.ID = t.ID
+			*t = merged
+			return nil
+		}
+
+	case "application/json-patch+json":
+		var ops []jsonPatchOp
+		if err := json.Unmarshal(body, &ops); err != nil {
+			http.Error(w, `{"error": "Invalid json patch body"}`, http.StatusBadRequest)
+			return
+		}
+		mutate = func(t *Task) error {
+			doc, err := taskToMap(*t)
+			if err != nil {
+				return err
+			}
+			if err := applyJSONPatch(doc, ops); err != nil {
+				return err
+			}
+			patched, err := mapToTask(doc)
+			if err != nil {
+				return err
+			}
+			patched.ID = t.ID
+			*t = patched
+			return nil
+		}
+
+	default:
+		http.Error(w, `{"error": "Unsupported Content-Type"}`, http.StatusUnsupportedMediaType)
+		return
+	}
+
+	task, err := s.store.Patch(r.Context(), id, expectedVersion, mutate)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		http.Error(w, `{"error": "Task not found"}`, http.StatusNotFound)
+		return
+	case errors.Is(err, ErrConflict):
+		http.Error(w, `{"error": "Task was modified concurrently"}`, http.StatusPreconditionFailed)
+		return
+	case errors.Is(err, errPatchTest):
+		http.Error(w, `{"error": "Patch test operation failed"}`, http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+	taskOperationsTotal.WithLabelValues("patch").Inc()
+
+	setTaskHeaders(w, task)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(task)
+}
+
+func (s *Server) deleteTaskHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseTaskID(r)
+	if err != nil {
+		http.Error(w, `{"error": "Invalid task ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	current, err := s.store.Get(r.Context(), id)
+	if errors.Is(err, ErrNotFound) {
+		http.Error(w, `{"error": "Task not found"}`, http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, `{"error": "Could not delete task"}`, http.StatusInternalServerError)
+		return
+	}
+
+	expectedVersion, status := s.checkPreconditions(w, r, current)
+	if status != 0 {
+		writePreconditionError(w, status)
+		return
+	}
+
+	err = s.store.Delete(r.Context(), id, expectedVersion)
+	if errors.Is(err, ErrNotFound) {
+		http.Error(w, `{"error": "Task not found"}`, http.StatusNotFound)
+		return
+	} else if errors.Is(err, ErrConflict) {
+		http.Error(w, `{"error": "Task was modified concurrently"}`, http.StatusPreconditionFailed)
+		return
+	} else if err != nil {
+		http.Error(w, `{"error": "Could not delete task"}`, http.StatusInternalServerError)
+		return
+	}
+	tasksTotal.Dec()
+	taskOperationsTotal.WithLabelValues("delete").Inc()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func parseTaskID(r *http.Request) (int, error) {
+	idStr := chi.URLParam(r, "taskID")
+	return strconv.Atoi(idStr)
+}
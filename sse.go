@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const sseHeartbeatInterval = 15 * time.Second
+
+// watchTasksHandler implements GET /tasks/watch, streaming task mutations
+// as Server-Sent Events. A "since" query parameter replays events recorded
+// after that revision before switching to live updates.
+func (s *Server) watchTasksHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, `{"error": "Streaming unsupported"}`, http.StatusInternalServerError)
+		return
+	}
+
+	var since int64
+	if raw := r.URL.Query().Get("since"); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			http.Error(w, `{"error": "Invalid 'since' query parameter"}`, http.StatusBadRequest)
+			return
+		}
+		since = parsed
+	}
+
+	events, cancel := s.store.Watch(r.Context(), since)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+
+		case <-s.shuttingDown:
+			return
+
+		case evt, ok := <-events:
+			if !ok {
+				return
+			}
+			body, err := json.Marshal(evt)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", evt.Type, body)
+			flusher.Flush()
+
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func newTestRouter(srv *Server) http.Handler {
+	r := chi.NewRouter()
+	r.Route("/tasks", func(r chi.Router) {
+		r.Post("/", srv.createTaskHandler)
+		r.Get("/watch", srv.watchTasksHandler)
+	})
+	return r
+}
+
+func TestWatchTasksHandler_ReceivesCreateEvent(t *testing.T) {
+	srv := newTestServer()
+	ts := httptest.NewServer(newTestRouter(srv))
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/tasks/watch")
+	if err != nil {
+		t.Fatalf("could not open watch stream: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("got Content-Type %q, want text/event-stream", ct)
+	}
+
+	if _, err := http.Post(ts.URL+"/tasks/", "application/json",
+		strings.NewReader(`{"description": "watched"}`)); err != nil {
+		t.Fatalf("could not create task: %v", err)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	done := make(chan string, 1)
+	go func() {
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				done <- ""
+				return
+			}
+			if strings.HasPrefix(line, "event: ") {
+				done <- strings.TrimSpace(strings.TrimPrefix(line, "event: "))
+				return
+			}
+		}
+	}()
+
+	select {
+	case evtType := <-done:
+		if evtType != string(TaskEventCreated) {
+			t.Errorf("got event type %q, want %q", evtType, TaskEventCreated)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for SSE event")
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound is returned by a TaskStore when the requested task does not exist.
+var ErrNotFound = errors.New("task not found")
+
+// ErrConflict is returned by a TaskStore when a write's expected version does
+// not match the task's current version (optimistic concurrency failure).
+var ErrConflict = errors.New("task version conflict")
+
+// TaskEventType identifies the kind of mutation a TaskEvent represents.
+type TaskEventType string
+
+const (
+	TaskEventCreated TaskEventType = "created"
+	TaskEventUpdated TaskEventType = "updated"
+	TaskEventDeleted TaskEventType = "deleted"
+)
+
+// TaskEvent describes a single mutation emitted by a TaskStore's Watch
+// stream. Revision is a per-store monotonically increasing sequence number
+// that callers can pass back as Watch's "since" to resume after it.
+type TaskEvent struct {
+	Type     TaskEventType `json:"type"`
+	Task     Task          `json:"task"`
+	Revision int64         `json:"revision"`
+}
+
+// TaskStore is the persistence abstraction for tasks. Implementations back
+// it with whatever storage engine they like (in-memory, SQL, etcd) as long
+// as they honor optimistic concurrency via each task's Version: a write
+// whose expectedVersion is non-zero must fail with ErrConflict if it does
+// not match the task's current version.
+type TaskStore interface {
+	Create(ctx context.Context, description string) (Task, error)
+	Get(ctx context.Context, id int) (Task, error)
+	List(ctx context.Context) ([]Task, error)
+
+	// Update replaces description/done on the task identified by id. A nil
+	// field is left unchanged. If expectedVersion is non-zero, the update
+	// only applies when it matches the task's current version.
+	Update(ctx context.Context, id int, description *string, done *bool, expectedVersion int64) (Task, error)
+
+	// Patch applies mutate to a copy of the current task and persists the
+	// result, subject to the same optimistic concurrency rule as Update.
+	Patch(ctx context.Context, id int, expectedVersion int64, mutate func(*Task) error) (Task, error)
+
+	Delete(ctx context.Context, id int, expectedVersion int64) error
+
+	// Watch returns a channel of events starting after the given revision
+	// (0 replays nothing prior) and a cancel func that must be called once
+	// the subscriber is done to release resources.
+	Watch(ctx context.Context, since int64) (<-chan TaskEvent, func())
+}
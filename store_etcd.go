@@ -0,0 +1,266 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// EtcdStore is a TaskStore backed by etcd. Each task is stored as JSON at
+// key "/tasks/{id}"; a task's ModRevision doubles as its Version, which
+// lets Update/Patch/Delete use etcd transactions for compare-and-swap
+// instead of a process-local mutex.
+type EtcdStore struct {
+	client *clientv3.Client
+	prefix string
+	ids    *EtcdSequence
+}
+
+const etcdTaskPrefix = "/tasks/"
+
+// NewEtcdStore wraps an etcd client. It assumes the client is already
+// connected to a cluster reachable at the configured endpoints.
+func NewEtcdStore(client *clientv3.Client) *EtcdStore {
+	return &EtcdStore{
+		client: client,
+		prefix: etcdTaskPrefix,
+		ids:    NewEtcdSequence(client, "/tasks/_next_id"),
+	}
+}
+
+func (s *EtcdStore) key(id int) string {
+	return s.prefix + strconv.Itoa(id)
+}
+
+func (s *EtcdStore) Create(ctx context.Context, description string) (Task, error) {
+	id, err := s.ids.Next(ctx)
+	if err != nil {
+		return Task{}, err
+	}
+
+	task := Task{ID: id, Description: description, Done: false, UpdatedAt: time.Now().UTC()}
+	body, err := json.Marshal(task)
+	if err != nil {
+		return Task{}, err
+	}
+
+	resp, err := s.client.Put(ctx, s.key(id), string(body))
+	if err != nil {
+		return Task{}, err
+	}
+	task.Version = resp.Header.Revision
+	return task, nil
+}
+
+func (s *EtcdStore) Get(ctx context.Context, id int) (Task, error) {
+	resp, err := s.client.Get(ctx, s.key(id))
+	if err != nil {
+		return Task{}, err
+	}
+	if len(resp.Kvs) == 0 {
+		return Task{}, ErrNotFound
+	}
+	return decodeEtcdTask(resp.Kvs[0])
+}
+
+func (s *EtcdStore) List(ctx context.Context) ([]Task, error) {
+	resp, err := s.client.Get(ctx, s.prefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]Task, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		if strings.HasSuffix(string(kv.Key), "_next_id") {
+			continue
+		}
+		task, err := decodeEtcdTask(kv)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+func decodeEtcdTask(kv *mvccpb.KeyValue) (Task, error) {
+	var task Task
+	if err := json.Unmarshal(kv.Value, &task); err != nil {
+		return Task{}, err
+	}
+	task.Version = kv.ModRevision
+	return task, nil
+}
+
+func (s *EtcdStore) Update(ctx context.Context, id int, description *string, done *bool, expectedVersion int64) (Task, error) {
+	return s.Patch(ctx, id, expectedVersion, func(t *Task) error {
+		if description != nil {
+			t.Description = *description
+		}
+		if done != nil {
+			t.Done = *done
+		}
+		return nil
+	})
+}
+
+// Patch performs a read-modify-write guarded by an etcd transaction: the
+// write only commits if the key's ModRevision still matches what we read,
+// giving us safe concurrent updates without a global mutex.
+func (s *EtcdStore) Patch(ctx context.Context, id int, expectedVersion int64, mutate func(*Task) error) (Task, error) {
+	key := s.key(id)
+
+	for {
+		current, err := s.Get(ctx, id)
+		if err != nil {
+			return Task{}, err
+		}
+		if expectedVersion != 0 && expectedVersion != current.Version {
+			return Task{}, ErrConflict
+		}
+
+		updated := current
+		if err := mutate(&updated); err != nil {
+			return Task{}, err
+		}
+		updated.ID = id
+		updated.UpdatedAt = time.Now().UTC()
+
+		body, err := json.Marshal(updated)
+		if err != nil {
+			return Task{}, err
+		}
+
+		resp, err := s.client.Txn(ctx).
+			If(clientv3.Compare(clientv3.ModRevision(key), "=", current.Version)).
+			Then(clientv3.OpPut(key, string(body))).
+			Commit()
+		if err != nil {
+			return Task{}, err
+		}
+		if !resp.Succeeded {
+			if expectedVersion != 0 {
+				return Task{}, ErrConflict
+			}
+			// Someone else wrote concurrently and the caller didn't pin a
+			// version: retry against the new value.
+			continue
+		}
+
+		updated.Version = resp.Header.Revision
+		return updated, nil
+	}
+}
+
+func (s *EtcdStore) Delete(ctx context.Context, id int, expectedVersion int64) error {
+	key := s.key(id)
+	current, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if expectedVersion != 0 && expectedVersion != current.Version {
+		return ErrConflict
+	}
+
+	resp, err := s.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.ModRevision(key), "=", current.Version)).
+		Then(clientv3.OpDelete(key)).
+		Commit()
+	if err != nil {
+		return err
+	}
+	if !resp.Succeeded {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (s *EtcdStore) Watch(ctx context.Context, since int64) (<-chan TaskEvent, func()) {
+	out := make(chan TaskEvent, 32)
+	watchCtx, cancel := context.WithCancel(ctx)
+
+	opts := []clientv3.OpOption{clientv3.WithPrefix(), clientv3.WithPrevKV()}
+	if since > 0 {
+		opts = append(opts, clientv3.WithRev(since+1))
+	}
+	wch := s.client.Watch(watchCtx, s.prefix, opts...)
+
+	go func() {
+		defer close(out)
+		for resp := range wch {
+			for _, ev := range resp.Events {
+				if strings.HasSuffix(string(ev.Kv.Key), "_next_id") {
+					continue
+				}
+				evt := TaskEvent{Type: TaskEventUpdated, Revision: ev.Kv.ModRevision}
+				if ev.Type == mvccpb.DELETE {
+					evt.Type = TaskEventDeleted
+					_ = json.Unmarshal(ev.PrevKv.Value, &evt.Task)
+				} else {
+					if ev.IsCreate() {
+						evt.Type = TaskEventCreated
+					}
+					_ = json.Unmarshal(ev.Kv.Value, &evt.Task)
+					evt.Task.Version = ev.Kv.ModRevision
+				}
+				select {
+				case out <- evt:
+				default:
+				}
+			}
+		}
+	}()
+
+	return out, cancel
+}
+
+// EtcdSequence hands out monotonically increasing task IDs via an etcd CAS
+// loop, since etcd itself has no auto-increment primitive.
+type EtcdSequence struct {
+	client *clientv3.Client
+	key    string
+}
+
+func NewEtcdSequence(client *clientv3.Client, key string) *EtcdSequence {
+	return &EtcdSequence{client: client, key: key}
+}
+
+func (seq *EtcdSequence) Next(ctx context.Context) (int, error) {
+	for {
+		resp, err := seq.client.Get(ctx, seq.key)
+		if err != nil {
+			return 0, err
+		}
+
+		var current int64
+		var cmp clientv3.Cmp
+		if len(resp.Kvs) == 0 {
+			current = 0
+			cmp = clientv3.Compare(clientv3.CreateRevision(seq.key), "=", 0)
+		} else {
+			current, err = strconv.ParseInt(string(resp.Kvs[0].Value), 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("corrupt id sequence: %w", err)
+			}
+			cmp = clientv3.Compare(clientv3.ModRevision(seq.key), "=", resp.Kvs[0].ModRevision)
+		}
+
+		next := current + 1
+		txn, err := seq.client.Txn(ctx).
+			If(cmp).
+			Then(clientv3.OpPut(seq.key, strconv.FormatInt(next, 10))).
+			Commit()
+		if err != nil {
+			return 0, err
+		}
+		if txn.Succeeded {
+			return int(next), nil
+		}
+	}
+}
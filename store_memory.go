@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore is an in-process TaskStore backed by a map. It is the default
+// backend and doubles as the fake used by tests.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	tasks    map[int]Task
+	nextID   int
+	revision int64
+	history  []TaskEvent
+	subs     map[chan TaskEvent]struct{}
+}
+
+// NewMemoryStore returns an empty MemoryStore ready to use.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		tasks:  make(map[int]Task),
+		nextID: 1,
+		subs:   make(map[chan TaskEvent]struct{}),
+	}
+}
+
+func (s *MemoryStore) publish(evt TaskEvent) {
+	evt.Revision = int64(len(s.history)) + 1
+	s.history = append(s.history, evt)
+	for ch := range s.subs {
+		select {
+		case ch <- evt:
+		default:
+			// slow subscriber: drop the event rather than block writers.
+		}
+	}
+}
+
+func (s *MemoryStore) Create(ctx context.Context, description string) (Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.revision++
+	task := Task{
+		ID:          s.nextID,
+		Description: description,
+		Done:        false,
+		Version:     s.revision,
+		UpdatedAt:   time.Now().UTC(),
+	}
+	s.tasks[s.nextID] = task
+	s.nextID++
+
+	s.publish(TaskEvent{Type: TaskEventCreated, Task: task})
+	return task, nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, id int) (Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	task, ok := s.tasks[id]
+	if !ok {
+		return Task{}, ErrNotFound
+	}
+	return task, nil
+}
+
+func (s *MemoryStore) List(ctx context.Context) ([]Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tasks := make([]Task, 0, len(s.tasks))
+	for _, task := range s.tasks {
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+func (s *MemoryStore) Update(ctx context.Context, id int, description *string, done *bool, expectedVersion int64) (Task, error) {
+	return s.Patch(ctx, id, expectedVersion, func(t *Task) error {
+		if description != nil {
+			t.Description = *description
+		}
+		if done != nil {
+			t.Done = *done
+		}
+		return nil
+	})
+}
+
+func (s *MemoryStore) Patch(ctx context.Context, id int, expectedVersion int64, mutate func(*Task) error) (Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[id]
+	if !ok {
+		return Task{}, ErrNotFound
+	}
+	if expectedVersion != 0 && expectedVersion != task.Version {
+		return Task{}, ErrConflict
+	}
+
+	if err := mutate(&task); err != nil {
+		return Task{}, err
+	}
+
+	s.revision++
+	task.ID = id
+	task.Version = s.revision
+	task.UpdatedAt = time.Now().UTC()
+	s.tasks[id] = task
+
+	s.publish(TaskEvent{Type: TaskEventUpdated, Task: task})
+	return task, nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, id int, expectedVersion int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if expectedVersion != 0 && expectedVersion != task.Version {
+		return ErrConflict
+	}
+
+	delete(s.tasks, id)
+	s.revision++
+	s.publish(TaskEvent{Type: TaskEventDeleted, Task: task})
+	return nil
+}
+
+func (s *MemoryStore) Watch(ctx context.Context, since int64) (<-chan TaskEvent, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch := make(chan TaskEvent, 32)
+	for _, evt := range s.history {
+		if evt.Revision <= since {
+			continue
+		}
+		select {
+		case ch <- evt:
+		default:
+		}
+	}
+
+	s.subs[ch] = struct{}{}
+	cancel := func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.subs, ch)
+		close(ch)
+	}
+	return ch, cancel
+}
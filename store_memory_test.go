@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMemoryStore_CreateGetList(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	task, err := store.Create(ctx, "first")
+	if err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	if task.ID != 1 || task.Version != 1 {
+		t.Errorf("unexpected task from Create: %+v", task)
+	}
+
+	got, err := store.Get(ctx, task.ID)
+	if err != nil || got != task {
+		t.Errorf("Get returned %+v, %v; want %+v, nil", got, err, task)
+	}
+
+	list, err := store.List(ctx)
+	if err != nil || len(list) != 1 {
+		t.Errorf("List returned %+v, %v; want one task", list, err)
+	}
+}
+
+func TestMemoryStore_UpdateConflict(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	task, _ := store.Create(ctx, "original")
+
+	desc := "changed"
+	if _, err := store.Update(ctx, task.ID, &desc, nil, task.Version+1); !errors.Is(err, ErrConflict) {
+		t.Errorf("Update with stale version returned %v, want ErrConflict", err)
+	}
+
+	updated, err := store.Update(ctx, task.ID, &desc, nil, task.Version)
+	if err != nil {
+		t.Fatalf("Update with correct version returned error: %v", err)
+	}
+	if updated.Description != "changed" || updated.Version == task.Version {
+		t.Errorf("Update did not apply change or bump version: %+v", updated)
+	}
+}
+
+func TestMemoryStore_DeleteNotFound(t *testing.T) {
+	store := NewMemoryStore()
+	if err := store.Delete(context.Background(), 42, 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete of missing task returned %v, want ErrNotFound", err)
+	}
+}
+
+func TestMemoryStore_Watch(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	events, cancel := store.Watch(ctx, 0)
+	defer cancel()
+
+	if _, err := store.Create(ctx, "watched"); err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+
+	select {
+	case evt := <-events:
+		if evt.Type != TaskEventCreated {
+			t.Errorf("got event type %v, want %v", evt.Type, TaskEventCreated)
+		}
+	default:
+		t.Errorf("expected an event to be published after Create")
+	}
+}
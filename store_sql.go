@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// SQLStore is a TaskStore backed by database/sql. It works against either
+// SQLite or Postgres; the driver name and placeholder style are chosen by
+// the caller when the store is constructed, since the two drivers disagree
+// on bind-parameter syntax ($1 vs ?).
+type SQLStore struct {
+	db          *sql.DB
+	placeholder func(n int) string
+}
+
+// NewSQLStore wraps an already-opened *sql.DB. driver selects the bind
+// parameter style: "postgres" uses "$1", "$2", ...; anything else
+// (including "sqlite3") uses "?".
+func NewSQLStore(db *sql.DB, driver string) (*SQLStore, error) {
+	s := &SQLStore{db: db}
+	if driver == "postgres" {
+		s.placeholder = func(n int) string { return "$" + itoa(n) }
+	} else {
+		s.placeholder = func(n int) string { return "?" }
+	}
+
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS tasks (
+		id INTEGER PRIMARY KEY,
+		description TEXT NOT NULL,
+		done BOOLEAN NOT NULL DEFAULT 0,
+		version INTEGER NOT NULL DEFAULT 1,
+		updated_at TEXT NOT NULL
+	)`)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func itoa(n int) string {
+	if n == 0 {
+		return "0"
+	}
+	digits := []byte{}
+	for n > 0 {
+		digits = append([]byte{byte('0' + n%10)}, digits...)
+		n /= 10
+	}
+	return string(digits)
+}
+
+func (s *SQLStore) Create(ctx context.Context, description string) (Task, error) {
+	now := time.Now().UTC()
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO tasks (description, done, version, updated_at) VALUES (`+s.placeholder(1)+`, 0, 1, `+s.placeholder(2)+`)`,
+		description, now.Format(time.RFC3339Nano))
+	if err != nil {
+		return Task{}, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return Task{}, err
+	}
+	return Task{ID: int(id), Description: description, Done: false, Version: 1, UpdatedAt: now}, nil
+}
+
+func (s *SQLStore) Get(ctx context.Context, id int) (Task, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT id, description, done, version, updated_at FROM tasks WHERE id = `+s.placeholder(1), id)
+	return scanTask(row)
+}
+
+func (s *SQLStore) List(ctx context.Context) ([]Task, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, description, done, version, updated_at FROM tasks`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []Task
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, rows.Err()
+}
+
+// sqlScanner is satisfied by both *sql.Row and *sql.Rows.
+type sqlScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTask(row sqlScanner) (Task, error) {
+	var t Task
+	var updatedAt string
+	if err := row.Scan(&t.ID, &t.Description, &t.Done, &t.Version, &updatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Task{}, ErrNotFound
+		}
+		return Task{}, err
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, updatedAt)
+	if err != nil {
+		return Task{}, err
+	}
+	t.UpdatedAt = parsed
+	return t, nil
+}
+
+func (s *SQLStore) Update(ctx context.Context, id int, description *string, done *bool, expectedVersion int64) (Task, error) {
+	return s.Patch(ctx, id, expectedVersion, func(t *Task) error {
+		if description != nil {
+			t.Description = *description
+		}
+		if done != nil {
+			t.Done = *done
+		}
+		return nil
+	})
+}
+
+func (s *SQLStore) Patch(ctx context.Context, id int, expectedVersion int64, mutate func(*Task) error) (Task, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Task{}, err
+	}
+	defer tx.Rollback()
+
+	row := tx.QueryRowContext(ctx,
+		`SELECT id, description, done, version, updated_at FROM tasks WHERE id = `+s.placeholder(1), id)
+	t, err := scanTask(row)
+	if err != nil {
+		return Task{}, err
+	}
+	if expectedVersion != 0 && expectedVersion != t.Version {
+		return Task{}, ErrConflict
+	}
+
+	if err := mutate(&t); err != nil {
+		return Task{}, err
+	}
+	t.Version++
+	t.UpdatedAt = time.Now().UTC()
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE tasks SET description = `+s.placeholder(1)+`, done = `+s.placeholder(2)+`, version = `+s.placeholder(3)+`, updated_at = `+s.placeholder(4)+
+			` WHERE id = `+s.placeholder(5)+` AND version = `+s.placeholder(6),
+		t.Description, t.Done, t.Version, t.UpdatedAt.Format(time.RFC3339Nano), id, t.Version-1)
+	if err != nil {
+		return Task{}, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return Task{}, err
+	} else if n == 0 {
+		return Task{}, ErrConflict
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Task{}, err
+	}
+	return t, nil
+}
+
+func (s *SQLStore) Delete(ctx context.Context, id int, expectedVersion int64) error {
+	if expectedVersion != 0 {
+		current, err := s.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if current.Version != expectedVersion {
+			return ErrConflict
+		}
+	}
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM tasks WHERE id = `+s.placeholder(1), id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Watch is not supported by the SQL store: there is no changefeed to tail
+// without a vendor-specific extension (e.g. Postgres LISTEN/NOTIFY), so
+// watchers should be served from a backend that supports it.
+func (s *SQLStore) Watch(ctx context.Context, since int64) (<-chan TaskEvent, func()) {
+	ch := make(chan TaskEvent)
+	close(ch)
+	return ch, func() {}
+}
@@ -0,0 +1,14 @@
+package main
+
+import "time"
+
+// Task is a single to-do item. Version increments on every write and is
+// used by stores to implement optimistic concurrency control; UpdatedAt
+// tracks when the task last changed and backs the Last-Modified header.
+type Task struct {
+	ID          int       `json:"id"`
+	Description string    `json:"description"`
+	Done        bool      `json:"done"`
+	Version     int64     `json:"version"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}